@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var digestsBucket = []byte("digests")
+
+// Bolt is a file-backed Cache, persisting entries to a BoltDB file so a
+// version-checker restart doesn't cause a thundering herd of HEAD requests
+// against upstream registries.
+type Bolt struct {
+	db *bolt.DB
+}
+
+// NewBolt opens (creating if necessary) a BoltDB file at path for use as a
+// Cache. The returned Bolt must be closed when no longer needed.
+func NewBolt(path string) (*Bolt, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second * 5})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache db %s: %s", path, err)
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(digestsBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialise cache db %s: %s", path, err)
+	}
+
+	return &Bolt{db: db}, nil
+}
+
+// Get returns the cached Entry for key, if present.
+func (b *Bolt) Get(_ context.Context, key string) (Entry, bool, error) {
+	var (
+		entry Entry
+		found bool
+	)
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(digestsBucket).Get([]byte(key))
+		if raw == nil {
+			return nil
+		}
+
+		found = true
+		return json.Unmarshal(raw, &entry)
+	})
+
+	return entry, found, err
+}
+
+// Set stores entry under key.
+func (b *Bolt) Set(_ context.Context, key string, entry Entry) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(digestsBucket).Put([]byte(key), raw)
+	})
+}
+
+// Close releases the underlying BoltDB file handle.
+func (b *Bolt) Close() error {
+	return b.db.Close()
+}
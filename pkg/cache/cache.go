@@ -0,0 +1,36 @@
+// Package cache persists registry digest lookups across restarts, so
+// version-checker doesn't cause a thundering herd of HEAD requests against
+// upstream registries every time it restarts.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Entry is a single cached digest lookup result.
+type Entry struct {
+	Digest      string
+	ETag        string
+	LastChecked time.Time
+	TTL         time.Duration
+
+	// OS, Arch, and Variant are the platform resolved from a manifest list
+	// on the original cache miss, so later hits and revalidations can
+	// report back the same platform without re-resolving it.
+	OS      string
+	Arch    string
+	Variant string
+}
+
+// Fresh reports whether Entry is still within its TTL as of now.
+func (e Entry) Fresh(now time.Time) bool {
+	return now.Sub(e.LastChecked) < e.TTL
+}
+
+// Cache stores digest lookup results, keyed by a full image reference (e.g.
+// "ghcr.io/jetstack/version-checker:v1.0.0").
+type Cache interface {
+	Get(ctx context.Context, key string) (Entry, bool, error)
+	Set(ctx context.Context, key string, entry Entry) error
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEntryFresh(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	tests := map[string]struct {
+		entry Entry
+		exp   bool
+	}{
+		"within ttl": {
+			entry: Entry{LastChecked: now.Add(-time.Second * 30), TTL: time.Minute},
+			exp:   true,
+		},
+		"past ttl": {
+			entry: Entry{LastChecked: now.Add(-time.Minute * 2), TTL: time.Minute},
+			exp:   false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.entry.Fresh(now); got != test.exp {
+				t.Errorf("exp=%v got=%v", test.exp, got)
+			}
+		})
+	}
+}
+
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+	ctx := context.TODO()
+
+	if _, found, err := m.Get(ctx, "missing"); err != nil || found {
+		t.Fatalf("expected no entry, found=%v err=%v", found, err)
+	}
+
+	entry := Entry{Digest: "sha256:abcd", ETag: `"etag"`, LastChecked: time.Unix(1000, 0), TTL: time.Minute}
+	if err := m.Set(ctx, "ghcr.io/foo/bar:latest", entry); err != nil {
+		t.Fatal(err)
+	}
+
+	got, found, err := m.Get(ctx, "ghcr.io/foo/bar:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found {
+		t.Fatal("expected entry to be found")
+	}
+	if got != entry {
+		t.Errorf("unexpected entry, exp=%#v got=%#v", entry, got)
+	}
+}
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	manifest "github.com/jetstack/version-checker/pkg/client/docker/manifest"
+	"github.com/jetstack/version-checker/pkg/metrics"
+)
+
+// manifestClient is the subset of *manifest.ManifestClient used by
+// DigestCache, extracted as an interface so it can be substituted with a
+// fake registry backend in tests.
+type manifestClient interface {
+	Digest(ctx context.Context, ref manifest.Ref, platform manifest.Platform) (string, manifest.Platform, error)
+	HeadDigest(ctx context.Context, ref manifest.Ref, ifNoneMatch string) (manifest.HeadResult, error)
+}
+
+// DigestCache wraps a manifest client with a persistent Cache, so restarts
+// don't cause a thundering herd of HEAD requests against upstream
+// registries. Lookups within TTL are served from Cache; once stale, a HEAD
+// request conditional on the cached ETag is issued, and a 304 response
+// refreshes LastChecked without re-resolving the digest.
+type DigestCache struct {
+	client  manifestClient
+	cache   Cache
+	ttl     time.Duration
+	metrics *metrics.Metrics
+}
+
+// New builds a DigestCache backed by cache, fronting client. Entries are
+// considered fresh for ttl.
+func New(client *manifest.ManifestClient, cache Cache, ttl time.Duration) *DigestCache {
+	return &DigestCache{client: client, cache: cache, ttl: ttl}
+}
+
+// SetMetrics wires cache hit/miss counters into m. Optional; if never
+// called, hits and misses simply aren't recorded.
+func (d *DigestCache) SetMetrics(m *metrics.Metrics) {
+	d.metrics = m
+}
+
+// Digest returns ref's manifest digest, consulting the cache before issuing
+// any registry request.
+func (d *DigestCache) Digest(ctx context.Context, ref manifest.Ref, platform manifest.Platform) (string, manifest.Platform, error) {
+	key := ref.String()
+	now := time.Now()
+
+	entry, found, err := d.cache.Get(ctx, key)
+	if err != nil {
+		return "", manifest.Platform{}, err
+	}
+
+	if found && entry.Fresh(now) {
+		d.recordHit(ref.Registry)
+		return entry.Digest, entryPlatform(entry), nil
+	}
+
+	d.recordMiss(ref.Registry)
+
+	if found {
+		head, err := d.client.HeadDigest(ctx, ref, entry.ETag)
+		if err != nil {
+			return "", manifest.Platform{}, err
+		}
+
+		if head.NotModified {
+			entry.LastChecked = now
+			if err := d.cache.Set(ctx, key, entry); err != nil {
+				return "", manifest.Platform{}, err
+			}
+			return entry.Digest, entryPlatform(entry), nil
+		}
+	}
+
+	digest, resolvedPlatform, err := d.client.Digest(ctx, ref, platform)
+	if err != nil {
+		return "", manifest.Platform{}, err
+	}
+
+	// Best-effort: capture the tag's current ETag so the next lookup after
+	// TTL expiry can revalidate with a conditional HEAD instead of a full
+	// re-resolution. A failure here just means the next lookup falls back
+	// to an unconditional Digest call.
+	var etag string
+	if head, err := d.client.HeadDigest(ctx, ref, ""); err == nil {
+		etag = head.ETag
+	}
+
+	if err := d.cache.Set(ctx, key, Entry{
+		Digest:      digest,
+		ETag:        etag,
+		LastChecked: now,
+		TTL:         d.ttl,
+		OS:          resolvedPlatform.OS,
+		Arch:        resolvedPlatform.Arch,
+		Variant:     resolvedPlatform.Variant,
+	}); err != nil {
+		return "", manifest.Platform{}, err
+	}
+
+	return digest, resolvedPlatform, nil
+}
+
+// entryPlatform reconstructs the Platform resolved on the original cache
+// miss from a stored Entry.
+func entryPlatform(entry Entry) manifest.Platform {
+	return manifest.Platform{OS: entry.OS, Arch: entry.Arch, Variant: entry.Variant}
+}
+
+func (d *DigestCache) recordHit(registry string) {
+	if d.metrics != nil {
+		d.metrics.IncCacheHit(registry)
+	}
+}
+
+func (d *DigestCache) recordMiss(registry string) {
+	if d.metrics != nil {
+		d.metrics.IncCacheMiss(registry)
+	}
+}
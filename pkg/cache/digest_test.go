@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	manifest "github.com/jetstack/version-checker/pkg/client/docker/manifest"
+)
+
+type fakeManifestClient struct {
+	digestCalls int
+	headCalls   int
+
+	digest   string
+	etag     string
+	platform manifest.Platform
+}
+
+func (f *fakeManifestClient) Digest(_ context.Context, _ manifest.Ref, _ manifest.Platform) (string, manifest.Platform, error) {
+	f.digestCalls++
+	return f.digest, f.platform, nil
+}
+
+func (f *fakeManifestClient) HeadDigest(_ context.Context, _ manifest.Ref, ifNoneMatch string) (manifest.HeadResult, error) {
+	f.headCalls++
+	if ifNoneMatch != "" && ifNoneMatch == f.etag {
+		return manifest.HeadResult{NotModified: true}, nil
+	}
+	return manifest.HeadResult{Digest: f.digest, ETag: f.etag}, nil
+}
+
+func TestDigestCache(t *testing.T) {
+	ref := manifest.Ref{Registry: "ghcr.io", Repository: "jetstack/version-checker", Tag: "latest"}
+	resolved := manifest.Platform{OS: "linux", Arch: "arm64"}
+	fake := &fakeManifestClient{digest: "sha256:abcd", etag: `"v1"`, platform: resolved}
+
+	dc := &DigestCache{client: fake, cache: NewMemory(), ttl: time.Minute}
+
+	// The caller doesn't know the platform up front (e.g. a manifest list),
+	// so it passes the zero value; the resolved platform should come back.
+	digest, platform, err := dc.Digest(context.TODO(), ref, manifest.Platform{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != "sha256:abcd" {
+		t.Fatalf("unexpected digest on cold cache: %s", digest)
+	}
+	if platform != resolved {
+		t.Fatalf("unexpected platform on cold cache: %+v", platform)
+	}
+	if fake.digestCalls != 1 {
+		t.Fatalf("expected one Digest call on cold cache, got %d", fake.digestCalls)
+	}
+
+	// Still fresh: served straight from the cache, no registry calls at all,
+	// and the platform resolved on the miss above must still come back.
+	fake.digestCalls, fake.headCalls = 0, 0
+	if _, platform, err := dc.Digest(context.TODO(), ref, manifest.Platform{}); err != nil {
+		t.Fatal(err)
+	} else if platform != resolved {
+		t.Fatalf("unexpected platform on fresh cache hit: %+v", platform)
+	}
+	if fake.digestCalls != 0 || fake.headCalls != 0 {
+		t.Fatalf("expected a fresh cache hit to skip the registry entirely, got digestCalls=%d headCalls=%d", fake.digestCalls, fake.headCalls)
+	}
+
+	// Expire the entry, but the registry still reports the same ETag: we
+	// should revalidate via a conditional HEAD rather than a full Digest.
+	stale, found, err := dc.cache.Get(context.TODO(), ref.String())
+	if err != nil || !found {
+		t.Fatalf("expected the cold-cache lookup to have populated the cache, found=%v err=%v", found, err)
+	}
+	stale.LastChecked = time.Now().Add(-time.Hour)
+	if err := dc.cache.Set(context.TODO(), ref.String(), stale); err != nil {
+		t.Fatal(err)
+	}
+
+	fake.digestCalls, fake.headCalls = 0, 0
+	digest, platform, err = dc.Digest(context.TODO(), ref, manifest.Platform{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != "sha256:abcd" {
+		t.Errorf("unexpected digest after revalidation: %s", digest)
+	}
+	if platform != resolved {
+		t.Errorf("unexpected platform after revalidation: %+v", platform)
+	}
+	if fake.digestCalls != 0 {
+		t.Errorf("expected revalidation to avoid a full Digest call, got %d", fake.digestCalls)
+	}
+	if fake.headCalls != 1 {
+		t.Errorf("expected exactly one conditional HEAD, got %d", fake.headCalls)
+	}
+}
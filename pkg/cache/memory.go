@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+// Memory is an in-memory Cache. Unlike Bolt, it does not survive a restart.
+type Memory struct {
+	mu      sync.RWMutex
+	entries map[string]Entry
+}
+
+// NewMemory builds an empty Memory cache.
+func NewMemory() *Memory {
+	return &Memory{entries: make(map[string]Entry)}
+}
+
+// Get returns the cached Entry for key, if present.
+func (m *Memory) Get(_ context.Context, key string) (Entry, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, ok := m.entries[key]
+	return entry, ok, nil
+}
+
+// Set stores entry under key.
+func (m *Memory) Set(_ context.Context, key string, entry Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = entry
+	return nil
+}
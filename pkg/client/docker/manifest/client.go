@@ -0,0 +1,524 @@
+package docker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/jetstack/version-checker/pkg/client/util"
+)
+
+// Options configures authentication against an OCI distribution registry.
+type Options struct {
+	Username string
+	Password string
+
+	// Token, if set, is used directly as a bearer token against a Bearer
+	// challenge, bypassing the token exchange entirely. Used for statically
+	// provisioned credentials where the registry only accepts pre-minted
+	// tokens. OptionsFromDockerConfigJSON never sets it.
+	Token string
+
+	// Transport, if set, is used as the underlying http.RoundTripper for
+	// both manifest and token requests, e.g. metrics.RegistryRoundTripper
+	// to instrument calls to the registry. Defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+// AuthResponse is the response of a registry's Bearer token endpoint. Some
+// registries (notably ECR) use "access_token" rather than "token".
+type AuthResponse struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+func (a AuthResponse) token() string {
+	if a.Token != "" {
+		return a.Token
+	}
+	return a.AccessToken
+}
+
+// ManifestClient is a generic OCI Distribution v2 client. Rather than
+// hardcoding a single registry's token endpoint, it discovers how to
+// authenticate from the `WWW-Authenticate` challenge a registry returns on
+// an unauthenticated request, so the same client works against Docker Hub,
+// GHCR, GCR, ECR, Quay, and self-hosted Harbor/Distribution instances alike.
+type ManifestClient struct {
+	*http.Client
+	Options
+}
+
+func New(options Options) (*ManifestClient, error) {
+	client := &http.Client{
+		Timeout:   time.Second * 5,
+		Transport: options.Transport,
+	}
+
+	return &ManifestClient{
+		Options: options,
+		Client:  client,
+	}, nil
+}
+
+// Platform identifies a single architecture within a multi-arch manifest
+// list or OCI image index.
+type Platform struct {
+	OS      string
+	Arch    string
+	Variant string
+}
+
+func (p Platform) String() string {
+	if p.Variant != "" {
+		return fmt.Sprintf("%s/%s/%s", p.OS, p.Arch, p.Variant)
+	}
+	return fmt.Sprintf("%s/%s", p.OS, p.Arch)
+}
+
+// Digest returns the content digest of the manifest for the given image
+// reference. If the reference resolves to a manifest list or OCI image
+// index, the entry matching platform is resolved and its digest returned
+// instead of the index's own digest, along with the platform that was
+// actually selected. If platform is the zero value, it is guessed from the
+// reference's tag via util.OSArchFromTag; if the tag names no platform either,
+// the index's own digest is returned with a zero Platform. For a single
+// manifest, platform is returned as passed.
+//
+// For backward compatibility with callers that have the reference already
+// split into its parts, see DigestFromParts.
+func (c *ManifestClient) Digest(ctx context.Context, ref Ref, platform Platform) (string, Platform, error) {
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.Registry, ref.Repository, ref.Tag)
+
+	logrus.WithField("url", manifestURL).Debug("doing a HEAD request to fetch a digest")
+
+	res, err := c.do(ctx, http.MethodHead, ref, manifestURL, nil)
+	if err != nil {
+		return "", Platform{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		wwwAuthHeader := res.Header.Get("WWW-Authenticate")
+		if wwwAuthHeader == "" {
+			wwwAuthHeader = "not present"
+		}
+		return "", Platform{}, fmt.Errorf("registry responded to head request to %s with %q, auth: %q", manifestURL, res.Status, wwwAuthHeader)
+	}
+
+	digest := res.Header.Get("Docker-Content-Digest")
+
+	if !isManifestList(res.Header.Get("Content-Type")) {
+		return digest, platform, nil
+	}
+
+	logrus.WithField("url", manifestURL).Debug("manifest is a list, resolving platform-specific digest")
+
+	res, err = c.do(ctx, http.MethodGet, ref, manifestURL, nil)
+	if err != nil {
+		return "", Platform{}, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", Platform{}, err
+	}
+
+	var list manifestList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return "", Platform{}, fmt.Errorf("failed to parse manifest list for %s: %s", ref, err)
+	}
+
+	if platform.OS == "" && platform.Arch == "" {
+		platform.OS, platform.Arch = util.OSArchFromTag(ref.Tag)
+	}
+
+	if platform.OS == "" && platform.Arch == "" {
+		return digest, Platform{}, nil
+	}
+
+	for _, m := range list.Manifests {
+		if !platformMatches(m.Platform, platform) {
+			continue
+		}
+
+		return m.Digest, Platform{
+			OS:      m.Platform.OS,
+			Arch:    m.Platform.Architecture,
+			Variant: m.Platform.Variant,
+		}, nil
+	}
+
+	return "", Platform{}, fmt.Errorf("no manifest found in image index %s matching platform %q", ref, platform)
+}
+
+// HeadResult is the outcome of a conditional manifest HEAD request.
+type HeadResult struct {
+	// Digest and ETag are only populated when NotModified is false.
+	Digest      string
+	ETag        string
+	NotModified bool
+}
+
+// HeadDigest performs a HEAD request for ref's manifest, conditional on
+// ifNoneMatch. If the registry still considers ifNoneMatch current it
+// responds 304 and HeadResult.NotModified is true; otherwise Digest and ETag
+// reflect the manifest's current state. Pass an empty ifNoneMatch to always
+// fetch the current digest and ETag.
+func (c *ManifestClient) HeadDigest(ctx context.Context, ref Ref, ifNoneMatch string) (HeadResult, error) {
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.Registry, ref.Repository, ref.Tag)
+
+	var extraHeaders map[string]string
+	if ifNoneMatch != "" {
+		extraHeaders = map[string]string{"If-None-Match": ifNoneMatch}
+	}
+
+	res, err := c.do(ctx, http.MethodHead, ref, manifestURL, extraHeaders)
+	if err != nil {
+		return HeadResult{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified {
+		return HeadResult{NotModified: true}, nil
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return HeadResult{}, fmt.Errorf("registry responded to head request to %s with %q", manifestURL, res.Status)
+	}
+
+	return HeadResult{
+		Digest: res.Header.Get("Docker-Content-Digest"),
+		ETag:   res.Header.Get("ETag"),
+	}, nil
+}
+
+// DigestFromParts is a convenience wrapper around Digest for callers that
+// have the reference split into its repo/image/tag components, pointed at
+// Docker Hub. New callers should prefer ParseRef and Digest directly.
+func (c *ManifestClient) DigestFromParts(ctx context.Context, repo, image, tag string) (string, error) {
+	repository := image
+	if repo != "" {
+		repository = repo + "/" + image
+	}
+
+	digest, _, err := c.Digest(ctx, Ref{
+		Registry:   defaultRegistry,
+		Repository: repository,
+		Tag:        tag,
+	}, Platform{})
+
+	return digest, err
+}
+
+// manifestList is the subset of the Docker manifest list / OCI image index
+// schema needed to resolve a platform-specific digest.
+type manifestList struct {
+	Manifests []manifestListEntry `json:"manifests"`
+}
+
+type manifestListEntry struct {
+	Digest   string           `json:"digest"`
+	Platform manifestPlatform `json:"platform"`
+}
+
+type manifestPlatform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant"`
+}
+
+func isManifestList(contentType string) bool {
+	switch contentType {
+	case "application/vnd.docker.distribution.manifest.list.v2+json",
+		"application/vnd.oci.image.index.v1+json":
+		return true
+	default:
+		return false
+	}
+}
+
+func platformMatches(entry manifestPlatform, want Platform) bool {
+	if entry.OS != want.OS || entry.Architecture != want.Arch {
+		return false
+	}
+
+	return want.Variant == "" || entry.Variant == want.Variant
+}
+
+// do performs an OCI Distribution API request, transparently authenticating
+// against the registry's advertised challenge if the first attempt is
+// rejected with a 401. extraHeaders, if non-nil, is applied to every attempt,
+// e.g. an If-None-Match revalidation header.
+func (c *ManifestClient) do(ctx context.Context, method string, ref Ref, manifestURL string, extraHeaders map[string]string) (*http.Response, error) {
+	req, err := c.newManifestRequest(ctx, method, manifestURL)
+	if err != nil {
+		return nil, err
+	}
+	applyHeaders(req, extraHeaders)
+
+	res, err := c.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s manifest: %s", method, err)
+	}
+
+	if res.StatusCode != http.StatusUnauthorized {
+		return res, nil
+	}
+	defer res.Body.Close()
+
+	authHeader, err := c.authenticate(ctx, ref, res.Header.Get("WWW-Authenticate"))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err = c.newManifestRequest(ctx, method, manifestURL)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", authHeader)
+	applyHeaders(req, extraHeaders)
+
+	res, err = c.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s manifest: %s", method, err)
+	}
+
+	return res, nil
+}
+
+func applyHeaders(req *http.Request, headers map[string]string) {
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+}
+
+// cosignSignatureAnnotation is the OCI layer descriptor annotation cosign
+// stores a base64-encoded signature under.
+const cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+// ociArtifactManifest is the subset of the OCI image manifest schema needed
+// to locate a cosign signature's payload blob and detached signature.
+type ociArtifactManifest struct {
+	Layers []ociArtifactLayer `json:"layers"`
+}
+
+type ociArtifactLayer struct {
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// SignatureArtifact fetches a cosign convention signature artifact at ref
+// (typically the "sha256-<digest>.sig" tag of an image's repository) and
+// returns the raw signed payload from its sole layer, along with the
+// associated base64-encoded signature from that layer's
+// dev.cosignproject.cosign/signature annotation.
+func (c *ManifestClient) SignatureArtifact(ctx context.Context, ref Ref) ([]byte, string, error) {
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.Registry, ref.Repository, ref.Tag)
+
+	res, err := c.do(ctx, http.MethodGet, ref, manifestURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("registry responded to signature manifest request for %s with %q", manifestURL, res.Status)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var m ociArtifactManifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, "", fmt.Errorf("failed to parse signature manifest for %s: %s", ref, err)
+	}
+
+	if len(m.Layers) != 1 {
+		return nil, "", fmt.Errorf("expected exactly one layer in signature artifact %s, got %d", ref, len(m.Layers))
+	}
+
+	layer := m.Layers[0]
+
+	sigBase64, ok := layer.Annotations[cosignSignatureAnnotation]
+	if !ok {
+		return nil, "", fmt.Errorf("signature artifact %s has no %s annotation", ref, cosignSignatureAnnotation)
+	}
+
+	blobURL := fmt.Sprintf("https://%s/v2/%s/blobs/%s", ref.Registry, ref.Repository, layer.Digest)
+
+	res, err = c.do(ctx, http.MethodGet, ref, blobURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("registry responded to signature blob request for %s with %q", blobURL, res.Status)
+	}
+
+	payload, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return payload, sigBase64, nil
+}
+
+func (c *ManifestClient) newManifestRequest(ctx context.Context, method, manifestURL string) (*http.Request, error) {
+	req, err := http.NewRequest(method, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Add("Accept", "application/vnd.docker.distribution.manifest.v2+json")
+	req.Header.Add("Accept", "application/vnd.docker.distribution.manifest.list.v2+json")
+	req.Header.Add("Accept", "application/vnd.oci.image.manifest.v1+json")
+	req.Header.Add("Accept", "application/vnd.oci.image.index.v1+json")
+	req.Header.Add("Accept", "application/vnd.docker.distribution.manifest.v1+json")
+
+	return req, nil
+}
+
+// challenge is a parsed `WWW-Authenticate` header, per RFC 7235.
+type challenge struct {
+	scheme  string
+	realm   string
+	service string
+	scope   string
+}
+
+var challengeParamRE = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+func parseChallenge(header string) challenge {
+	fields := strings.SplitN(header, " ", 2)
+
+	ch := challenge{scheme: fields[0]}
+	if len(fields) == 1 {
+		return ch
+	}
+
+	for _, m := range challengeParamRE.FindAllStringSubmatch(fields[1], -1) {
+		switch m[1] {
+		case "realm":
+			ch.realm = m[2]
+		case "service":
+			ch.service = m[2]
+		case "scope":
+			ch.scope = m[2]
+		}
+	}
+
+	return ch
+}
+
+// authenticate inspects a registry's `WWW-Authenticate` challenge and
+// returns the value to set as the `Authorization` header on a retried
+// request. It supports the Bearer token flow used by Docker Hub, GHCR, GCR,
+// ECR, and Quay, HTTP Basic auth used by some self-hosted Harbor/Distribution
+// deployments, and falls back to an anonymous Bearer token request when no
+// credentials are configured.
+func (c *ManifestClient) authenticate(ctx context.Context, ref Ref, wwwAuthHeader string) (string, error) {
+	if wwwAuthHeader == "" {
+		return "", fmt.Errorf("registry %s returned 401 with no WWW-Authenticate challenge", ref.Registry)
+	}
+
+	ch := parseChallenge(wwwAuthHeader)
+
+	switch strings.ToLower(ch.scheme) {
+	case "basic":
+		if c.Options.Username == "" {
+			return "", fmt.Errorf("registry %s requires basic auth credentials", ref.Registry)
+		}
+		ba := base64.StdEncoding.EncodeToString([]byte(c.Options.Username + ":" + c.Options.Password))
+		return "Basic " + ba, nil
+
+	case "bearer":
+		token, err := c.bearerToken(ctx, ch)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+
+	default:
+		return "", fmt.Errorf("unsupported auth scheme %q advertised by %s", ch.scheme, ref.Registry)
+	}
+}
+
+func (c *ManifestClient) bearerToken(ctx context.Context, ch challenge) (string, error) {
+	if c.Options.Token != "" {
+		return c.Options.Token, nil
+	}
+
+	if ch.realm == "" {
+		return "", fmt.Errorf("no realm present in bearer challenge")
+	}
+
+	u, err := url.Parse(ch.realm)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	if ch.service != "" {
+		q.Set("service", ch.service)
+	}
+	if ch.scope != "" {
+		q.Set("scope", ch.scope)
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+
+	// A Bearer realm may itself require Basic auth to hand out a scoped
+	// token, e.g. for private repositories. An empty Username/Password
+	// results in an anonymous, pull-only token on registries that support
+	// it.
+	if c.Options.Username != "" {
+		req.SetBasicAuth(c.Options.Username, c.Options.Password)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch auth token from %s: %s", ch.realm, string(body))
+	}
+
+	var authResp AuthResponse
+	if err := json.Unmarshal(body, &authResp); err != nil {
+		return "", err
+	}
+
+	if authResp.token() == "" {
+		return "", fmt.Errorf("auth response from %s contained no token", ch.realm)
+	}
+
+	return authResp.token(), nil
+}
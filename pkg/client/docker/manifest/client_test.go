@@ -0,0 +1,115 @@
+package docker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// redirectingTransport rewrites every request's scheme/host to point at a
+// test server, regardless of what the request was originally addressed to,
+// so ManifestClient's hardcoded "https://<registry>/..." URLs can be
+// exercised against an httptest.Server.
+type redirectingTransport struct {
+	target *url.URL
+}
+
+func (t *redirectingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+const manifestListBody = `{
+	"manifests": [
+		{"digest": "sha256:amd64digest", "platform": {"os": "linux", "architecture": "amd64"}},
+		{"digest": "sha256:arm64digest", "platform": {"os": "linux", "architecture": "arm64"}}
+	]
+}`
+
+func newManifestListServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.list.v2+json")
+		w.Header().Set("Docker-Content-Digest", "sha256:listdigest")
+
+		if r.Method == http.MethodHead {
+			return
+		}
+
+		if _, err := w.Write([]byte(manifestListBody)); err != nil {
+			t.Fatal(err)
+		}
+	}))
+}
+
+func newTestManifestClient(t *testing.T, srv *httptest.Server) *ManifestClient {
+	t.Helper()
+
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := New(Options{Transport: &redirectingTransport{target: target}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestManifestClientDigestResolvesMixedArchList(t *testing.T) {
+	srv := newManifestListServer(t)
+	defer srv.Close()
+
+	c := newTestManifestClient(t, srv)
+	ref := Ref{Registry: "example.com", Repository: "jetstack/version-checker", Tag: "latest"}
+
+	digest, platform, err := c.Digest(context.TODO(), ref, Platform{OS: "linux", Arch: "arm64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if digest != "sha256:arm64digest" {
+		t.Errorf("expected the arm64 entry's digest, got %s", digest)
+	}
+	if platform != (Platform{OS: "linux", Arch: "arm64"}) {
+		t.Errorf("unexpected resolved platform: %+v", platform)
+	}
+}
+
+func TestManifestClientDigestFallsBackToTagPlatform(t *testing.T) {
+	srv := newManifestListServer(t)
+	defer srv.Close()
+
+	c := newTestManifestClient(t, srv)
+	ref := Ref{Registry: "example.com", Repository: "jetstack/version-checker", Tag: "v1.0.0-linux-amd64"}
+
+	digest, platform, err := c.Digest(context.TODO(), ref, Platform{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if digest != "sha256:amd64digest" {
+		t.Errorf("expected the amd64 entry's digest resolved from the tag, got %s", digest)
+	}
+	if platform != (Platform{OS: "linux", Arch: "amd64"}) {
+		t.Errorf("unexpected resolved platform: %+v", platform)
+	}
+}
+
+func TestManifestClientDigestNoMatchingPlatform(t *testing.T) {
+	srv := newManifestListServer(t)
+	defer srv.Close()
+
+	c := newTestManifestClient(t, srv)
+	ref := Ref{Registry: "example.com", Repository: "jetstack/version-checker", Tag: "latest"}
+
+	if _, _, err := c.Digest(context.TODO(), ref, Platform{OS: "windows", Arch: "amd64"}); err == nil {
+		t.Error("expected an error when no manifest list entry matches the requested platform")
+	}
+}
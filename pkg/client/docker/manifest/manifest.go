@@ -1,142 +1,150 @@
 package docker
 
 import (
-	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"github.com/sirupsen/logrus"
-	"io/ioutil"
-	"net/http"
-	"net/url"
-	"time"
+	"regexp"
+	"strings"
 )
 
-const (
-	tokenURL = "https://auth.docker.io/token"
-	manifestURL = "https://registry.hub.docker.com/v2/%s/%s/manifests/%s"
-)
-
-type Options struct {
-	Username string
-	Password string
-}
+// defaultRegistry is used when an image reference has no explicit registry
+// host, matching the behaviour of `docker pull`.
+const defaultRegistry = "registry-1.docker.io"
 
-type AuthResponse struct {
-	Token string `json:"token"`
-}
+// defaultTag is used when an image reference has no explicit tag or digest.
+const defaultTag = "latest"
 
-type ManifestClient struct {
-	*http.Client
-	Options
+// Ref is a fully parsed OCI image reference, split into the registry host,
+// repository path, and tag (or digest).
+type Ref struct {
+	Registry   string
+	Repository string
+	Tag        string
 }
 
-func New(options Options) (*ManifestClient, error) {
-	client := &http.Client{
-		Timeout: time.Second * 5,
-	}	
-
-	return &ManifestClient{
-		Options: options,
-		Client:  client,
-	}, nil
+func (r Ref) String() string {
+	return r.Registry + "/" + r.Repository + ":" + r.Tag
 }
 
-func (c *ManifestClient) Digest(ctx context.Context, repo, image, tag string) (string, error) {
-	token, err := c.getAuthToken(ctx, repo, image)
-	if err != nil {
-		return "", err
+// ParseRef parses an image reference such as
+// "ghcr.io/jetstack/version-checker:v1.0.0", "gcr.io/distroless/static",
+// or "nginx:latest" (assumed to live on Docker Hub) into its registry,
+// repository, and tag components.
+func ParseRef(image string) (Ref, error) {
+	if image == "" {
+		return Ref{}, errors.New("cannot parse empty image reference")
 	}
 
-	url := fmt.Sprintf(manifestURL, repo, image, tag)
+	registry := defaultRegistry
+	rest := image
 
-	req, err := http.NewRequest("HEAD", url, nil)
-	if err != nil {
-		return "", err
+	if idx := strings.IndexByte(image, '/'); idx != -1 {
+		candidate := image[:idx]
+		if looksLikeRegistry(candidate) {
+			registry = candidate
+			rest = image[idx+1:]
+		}
 	}
 
-	req = req.WithContext(ctx)
-	req.Header.Add("Authorization", "Bearer " + token)
-	req.Header.Add("Accept", "application/vnd.docker.distribution.manifest.v2+json")
-	req.Header.Add("Accept", "application/vnd.docker.distribution.manifest.list.v2+json")
-	req.Header.Add("Accept", "application/vnd.docker.distribution.manifest.v1+json")
+	if rest == "" {
+		return Ref{}, errors.New("cannot parse image reference with no repository")
+	}
 
-	logrus.WithField("url", url).Debug("Doing a HEAD request to fetch a digest")
+	repository, tag := splitRepositoryTag(rest)
 
-	res, err := c.Client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to get docker manifest: %s", err)
+	// Docker Hub implicitly namespaces unqualified images under "library",
+	// e.g. "nginx" -> "library/nginx".
+	if registry == defaultRegistry && strings.IndexByte(repository, '/') == -1 {
+		repository = "library/" + repository
 	}
 
-	defer res.Body.Close()
+	return Ref{
+		Registry:   registry,
+		Repository: repository,
+		Tag:        tag,
+	}, nil
+}
 
-	if res.StatusCode != 200 {
-		wwwAuthHeader := res.Header.Get("www-authenticate")
-		if wwwAuthHeader == "" {
-			wwwAuthHeader = "not present"
-		}
-		return "", fmt.Errorf("registry responded to head request to %s with %q, auth: %q", url, res.Status, wwwAuthHeader)
+// looksLikeRegistry reports whether the first path segment of an image
+// reference is a registry host, rather than the first element of the
+// repository path. Registry hosts either contain a "." or ":" (a domain
+// name or a domain:port pair) or are the literal "localhost".
+func looksLikeRegistry(segment string) bool {
+	if segment == "localhost" {
+		return true
 	}
 
-	logrus.WithField("digest", res.Header.Get("Docker-Content-Digest")).Debug("Retrieved digest")
+	for _, c := range segment {
+		if c == '.' || c == ':' {
+			return true
+		}
+	}
 
-	return res.Header.Get("Docker-Content-Digest"), nil
+	return false
 }
 
-func authUrl(repo, image string) (string, error) {
-	u, err := url.Parse(tokenURL)
-	if err != nil {
-		return "", err
+func splitRepositoryTag(ref string) (string, string) {
+	if idx := strings.LastIndexByte(ref, '@'); idx != -1 {
+		return ref[:idx], ref[idx+1:]
 	}
 
-	scope := fmt.Sprintf("repository:%s/%s:pull", repo, image)
-
-	q := u.Query()
-	q.Set("service", "registry.docker.io")
-	q.Set("scope", scope)
-	u.RawQuery = q.Encode()
+	if idx := strings.LastIndexByte(ref, ':'); idx != -1 && strings.IndexByte(ref[idx:], '/') == -1 {
+		return ref[:idx], ref[idx+1:]
+	}
 
-	return u.String(), nil
+	return ref, defaultTag
 }
 
-func (c *ManifestClient) getAuthToken(ctx context.Context, repo, image string) (string, error) {
-	url, err := authUrl(repo, image)
-	if err != nil {
-		return "", err
-	}
+// DockerConfigJSON mirrors the `.dockerconfigjson` format used by both
+// `docker login` and Kubernetes `imagePullSecrets` of type
+// kubernetes.io/dockerconfigjson.
+type DockerConfigJSON struct {
+	Auths map[string]DockerConfigEntry `json:"auths"`
+}
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return "", err
-	}
+// DockerConfigEntry is a single registry's credentials within a
+// DockerConfigJSON.
+type DockerConfigEntry struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
 
-	if c.Options.Username != "" && c.Options.Password != "" {
-		ba := []byte(fmt.Sprintf("%s:%s", c.Options.Username, c.Options.Password))
+var authSplitRE = regexp.MustCompile(`^([^:]*):(.*)$`)
 
-		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString(ba))
+// OptionsFromDockerConfigJSON parses a DockerConfigJSON (typically sourced
+// from a Pod's imagePullSecrets) and returns the Options needed to
+// authenticate against the given registry host. The returned Options is the
+// zero value if no credentials are present for that registry.
+func OptionsFromDockerConfigJSON(data []byte, registry string) (Options, error) {
+	var cfg DockerConfigJSON
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Options{}, err
 	}
 
-	req = req.WithContext(ctx)
+	entry, ok := cfg.Auths[registry]
+	if !ok {
+		return Options{}, nil
+	}
 
-	resp, err := c.Client.Do(req)
-	if err != nil {
-		return "", err
+	if entry.Username != "" || entry.Password != "" {
+		return Options{Username: entry.Username, Password: entry.Password}, nil
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
+	if entry.Auth == "" {
+		return Options{}, nil
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", errors.New(string(body))
+	decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+	if err != nil {
+		return Options{}, err
 	}
 
-	response := new(AuthResponse)
-	if err := json.Unmarshal(body, response); err != nil {
-		return "", err
+	matches := authSplitRE.FindStringSubmatch(string(decoded))
+	if matches == nil {
+		return Options{}, errors.New("malformed auth field in docker config json")
 	}
 
-	return response.Token, nil
+	return Options{Username: matches[1], Password: matches[2]}, nil
 }
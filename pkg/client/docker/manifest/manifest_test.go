@@ -34,7 +34,7 @@ func TestDigest(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			digest, err := handler.Digest(context.TODO(), test.repo, test.image, test.tag)
+			digest, err := handler.DigestFromParts(context.TODO(), test.repo, test.image, test.tag)
 
 			if err != nil {
 				t.Fatal(err)
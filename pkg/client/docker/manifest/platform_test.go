@@ -0,0 +1,86 @@
+package docker
+
+import "testing"
+
+func TestPlatformMatches(t *testing.T) {
+	list := manifestList{
+		Manifests: []manifestListEntry{
+			{Digest: "sha256:amd64digest", Platform: manifestPlatform{OS: "linux", Architecture: "amd64"}},
+			{Digest: "sha256:arm64digest", Platform: manifestPlatform{OS: "linux", Architecture: "arm64"}},
+			{Digest: "sha256:armv7digest", Platform: manifestPlatform{OS: "linux", Architecture: "arm", Variant: "v7"}},
+			{Digest: "sha256:windowsdigest", Platform: manifestPlatform{OS: "windows", Architecture: "amd64"}},
+		},
+	}
+
+	tests := map[string]struct {
+		want      Platform
+		expDigest string
+		expFound  bool
+	}{
+		"matches linux/amd64": {
+			want:      Platform{OS: "linux", Arch: "amd64"},
+			expDigest: "sha256:amd64digest",
+			expFound:  true,
+		},
+		"matches linux/arm64": {
+			want:      Platform{OS: "linux", Arch: "arm64"},
+			expDigest: "sha256:arm64digest",
+			expFound:  true,
+		},
+		"matches arm variant exactly": {
+			want:      Platform{OS: "linux", Arch: "arm", Variant: "v7"},
+			expDigest: "sha256:armv7digest",
+			expFound:  true,
+		},
+		"no match for unknown platform falls through": {
+			want:     Platform{OS: "linux", Arch: "s390x"},
+			expFound: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				found  bool
+				digest string
+			)
+
+			for _, m := range list.Manifests {
+				if platformMatches(m.Platform, test.want) {
+					found = true
+					digest = m.Digest
+					break
+				}
+			}
+
+			if found != test.expFound {
+				t.Fatalf("expected found=%v, got=%v", test.expFound, found)
+			}
+
+			if found && digest != test.expDigest {
+				t.Errorf("unexpected digest, exp=%s got=%s", test.expDigest, digest)
+			}
+		})
+	}
+}
+
+func TestIsManifestList(t *testing.T) {
+	tests := map[string]struct {
+		contentType string
+		exp         bool
+	}{
+		"docker manifest list": {contentType: "application/vnd.docker.distribution.manifest.list.v2+json", exp: true},
+		"oci image index":      {contentType: "application/vnd.oci.image.index.v1+json", exp: true},
+		"single arch manifest": {contentType: "application/vnd.docker.distribution.manifest.v2+json", exp: false},
+		"oci single manifest":  {contentType: "application/vnd.oci.image.manifest.v1+json", exp: false},
+		"unrecognised/empty":   {contentType: "", exp: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := isManifestList(test.contentType); got != test.exp {
+				t.Errorf("%s: exp=%v got=%v", test.contentType, test.exp, got)
+			}
+		})
+	}
+}
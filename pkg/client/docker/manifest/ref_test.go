@@ -0,0 +1,60 @@
+package docker
+
+import "testing"
+
+func TestParseRef(t *testing.T) {
+	tests := map[string]struct {
+		image  string
+		expRef Ref
+		expErr bool
+	}{
+		"bare docker hub image gets library namespace and registry": {
+			image:  "nginx",
+			expRef: Ref{Registry: defaultRegistry, Repository: "library/nginx", Tag: defaultTag},
+		},
+		"docker hub image with explicit tag": {
+			image:  "n8nio/n8n:0.123.1-rpi",
+			expRef: Ref{Registry: defaultRegistry, Repository: "n8nio/n8n", Tag: "0.123.1-rpi"},
+		},
+		"ghcr image with namespace": {
+			image:  "ghcr.io/jetstack/version-checker:v1.0.0",
+			expRef: Ref{Registry: "ghcr.io", Repository: "jetstack/version-checker", Tag: "v1.0.0"},
+		},
+		"gcr image with no tag defaults to latest": {
+			image:  "gcr.io/distroless/static",
+			expRef: Ref{Registry: "gcr.io", Repository: "distroless/static", Tag: defaultTag},
+		},
+		"registry with port": {
+			image:  "localhost:5000/myimage:dev",
+			expRef: Ref{Registry: "localhost:5000", Repository: "myimage", Tag: "dev"},
+		},
+		"image pinned by digest": {
+			image:  "quay.io/coreos/etcd@sha256:abcd1234",
+			expRef: Ref{Registry: "quay.io", Repository: "coreos/etcd", Tag: "sha256:abcd1234"},
+		},
+		"empty image is an error": {
+			image:  "",
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ref, err := ParseRef(test.image)
+			if test.expErr {
+				if err == nil {
+					t.Errorf("%s: expected error, got none", test.image)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ref != test.expRef {
+				t.Errorf("%s: unexpected ref, exp=%#v got=%#v", test.image, test.expRef, ref)
+			}
+		})
+	}
+}
@@ -13,6 +13,9 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/jetstack/version-checker/pkg/notify"
+	"github.com/jetstack/version-checker/pkg/verify"
 )
 
 // Metrics is used to expose container image version checks as prometheus
@@ -20,14 +23,31 @@ import (
 type Metrics struct {
 	*http.Server
 
-	registry              *prometheus.Registry
-	containerImageVersion *prometheus.GaugeVec
-	log                   *logrus.Entry
+	registry                         *prometheus.Registry
+	containerImageVersion            *prometheus.GaugeVec
+	signatureVerified                *prometheus.GaugeVec
+	signatureVerificationErrorsTotal *prometheus.CounterVec
+	registryRequestDuration          *prometheus.HistogramVec
+	registryRequestsTotal            *prometheus.CounterVec
+	cacheHitsTotal                   *prometheus.CounterVec
+	cacheMissesTotal                 *prometheus.CounterVec
+	checkDuration                    *prometheus.HistogramVec
+	log                              *logrus.Entry
 
 	// container cache stores a cache of a container's current image, version,
 	// and the latest
 	containerCache map[string]cacheItem
 	mu             sync.Mutex
+
+	// notifier is fired whenever AddImage observes a container transition
+	// from latest to outdated, or an outdated container for the first time.
+	// Defaults to notify.NoOp.
+	notifier notify.Notifier
+
+	// verifier gates is_latest_version=1 on a successful signature
+	// verification for entries that opt in via entry.RequireSignature.
+	// Defaults to verify.NoOp.
+	verifier verify.Verifier
 }
 
 type cacheItem struct {
@@ -36,6 +56,7 @@ type cacheItem struct {
 	latestVersion  string
 	os             string
 	arch           string
+	isLatest       bool
 }
 
 // Entry is a struct containing a single metrics label set
@@ -49,6 +70,19 @@ type Entry struct {
 	LatestVersion  string
 	OS             string
 	Arch           string
+
+	// Digest is the resolved digest of LatestVersion, used to look up its
+	// signature when RequireSignature is set.
+	Digest string
+
+	// RequireSignature gates IsLatest on a successful signature
+	// verification via the configured Verifier. Callers should set this
+	// from verify.RequireSignature(annotations).
+	RequireSignature bool
+
+	// NamespaceAnnotations are the annotations of Namespace, passed through
+	// to notify.Event so Router can decide which sinks to notify.
+	NamespaceAnnotations map[string]string
 }
 
 func New(log *logrus.Entry) *Metrics {
@@ -63,17 +97,119 @@ func New(log *logrus.Entry) *Metrics {
 		},
 	)
 
+	signatureVerified := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "version_checker",
+			Name:      "signature_verified",
+			Help:      "Whether the container's image signature was successfully verified",
+		},
+		[]string{
+			"namespace", "pod", "container", "image",
+		},
+	)
+
+	signatureVerificationErrorsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "version_checker",
+			Name:      "signature_verification_errors_total",
+			Help:      "Total number of errors encountered while verifying a container's image signature",
+		},
+		[]string{
+			"namespace", "pod", "container", "image",
+		},
+	)
+
+	registryRequestDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "version_checker",
+			Name:      "registry_request_duration_seconds",
+			Help:      "Duration of HTTP requests made to upstream OCI registries",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10),
+		},
+		[]string{"registry", "method", "status_class"},
+	)
+
+	registryRequestsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "version_checker",
+			Name:      "registry_requests_total",
+			Help:      "Total number of HTTP requests made to upstream OCI registries",
+		},
+		[]string{"registry", "method", "status_class"},
+	)
+
+	cacheHitsTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "version_checker",
+			Name:      "cache_hits_total",
+			Help:      "Total number of digest cache hits",
+		},
+		[]string{"registry"},
+	)
+
+	cacheMissesTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "version_checker",
+			Name:      "cache_misses_total",
+			Help:      "Total number of digest cache misses",
+		},
+		[]string{"registry"},
+	)
+
+	checkDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "version_checker",
+			Name:      "check_duration_seconds",
+			Help:      "Duration of an end-to-end per-container version check",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 10),
+		},
+		[]string{"namespace"},
+	)
+
 	registry := prometheus.NewRegistry()
-	registry.MustRegister(containerImageVersion)
+	registry.MustRegister(
+		containerImageVersion,
+		signatureVerified,
+		signatureVerificationErrorsTotal,
+		registryRequestDuration,
+		registryRequestsTotal,
+		cacheHitsTotal,
+		cacheMissesTotal,
+		checkDuration,
+	)
 
 	return &Metrics{
-		log:                   log.WithField("module", "metrics"),
-		registry:              registry,
-		containerImageVersion: containerImageVersion,
-		containerCache:        make(map[string]cacheItem),
+		log:                              log.WithField("module", "metrics"),
+		registry:                         registry,
+		containerImageVersion:            containerImageVersion,
+		signatureVerified:                signatureVerified,
+		signatureVerificationErrorsTotal: signatureVerificationErrorsTotal,
+		registryRequestDuration:          registryRequestDuration,
+		registryRequestsTotal:            registryRequestsTotal,
+		cacheHitsTotal:                   cacheHitsTotal,
+		cacheMissesTotal:                 cacheMissesTotal,
+		checkDuration:                    checkDuration,
+		containerCache:                   make(map[string]cacheItem),
+		notifier:                         notify.NoOp{},
+		verifier:                         verify.NoOp{},
 	}
 }
 
+// SetNotifier configures the Notifier fired on version-drift events. If
+// never called, Metrics defaults to notify.NoOp and no notifications are
+// sent.
+func (m *Metrics) SetNotifier(notifier notify.Notifier) {
+	m.notifier = notifier
+}
+
+// SetVerifier configures the Verifier used to gate is_latest_version on
+// signature verification for entries with RequireSignature set. If never
+// called, Metrics defaults to verify.NoOp and every entry is treated as
+// verified.
+func (m *Metrics) SetVerifier(verifier verify.Verifier) {
+	m.verifier = verifier
+}
+
 // Run will run the metrics server
 func (m *Metrics) Run(servingAddress string) error {
 	router := http.NewServeMux()
@@ -105,9 +241,27 @@ func (m *Metrics) Run(servingAddress string) error {
 }
 
 func (m *Metrics) AddImage(entry *Entry) {
+	index := m.latestImageIndex(entry.Namespace, entry.Pod, entry.Container)
+
+	m.mu.Lock()
+	previous, hadPrevious := m.containerCache[index]
+	m.mu.Unlock()
+
 	// Remove old image url/version if it exists
 	m.RemoveImage(entry.Namespace, entry.Pod, entry.Container)
 
+	if entry.IsLatest && entry.RequireSignature {
+		if err := m.verifier.Verify(context.Background(), entry.ImageURL, entry.Digest); err != nil {
+			m.log.Errorf("signature verification failed for %s/%s/%s: %s",
+				entry.Namespace, entry.Pod, entry.Container, err)
+			m.IncSignatureVerificationErrors(entry)
+			m.SetSignatureVerified(entry, false)
+			entry.IsLatest = false
+		} else {
+			m.SetSignatureVerified(entry, true)
+		}
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -120,13 +274,37 @@ func (m *Metrics) AddImage(entry *Entry) {
 		m.buildLabels(entry),
 	).Set(isLatestF)
 
-	index := m.latestImageIndex(entry.Namespace, entry.Pod, entry.Container)
 	m.containerCache[index] = cacheItem{
 		image:          entry.ImageURL,
 		currentVersion: entry.CurrentVersion,
 		latestVersion:  entry.LatestVersion,
 		os:             entry.OS,
 		arch:           entry.Arch,
+		isLatest:       entry.IsLatest,
+	}
+
+	if !entry.IsLatest && (!hadPrevious || previous.isLatest) {
+		go m.notifyOutdated(entry)
+	}
+}
+
+// notifyOutdated fires the configured Notifier for entry. It runs in its own
+// goroutine so a slow or failing sink never blocks a metrics update.
+func (m *Metrics) notifyOutdated(entry *Entry) {
+	event := notify.Event{
+		Namespace:            entry.Namespace,
+		Pod:                  entry.Pod,
+		Container:            entry.Container,
+		Image:                entry.ImageURL,
+		CurrentVersion:       entry.CurrentVersion,
+		LatestVersion:        entry.LatestVersion,
+		CheckedAt:            time.Now(),
+		NamespaceAnnotations: entry.NamespaceAnnotations,
+	}
+
+	if err := m.notifier.Notify(context.Background(), event); err != nil {
+		m.log.Errorf("failed to send version-drift notification for %s/%s/%s: %s",
+			entry.Namespace, entry.Pod, entry.Container, err)
 	}
 }
 
@@ -152,9 +330,41 @@ func (m *Metrics) RemoveImage(namespace, pod, container string) {
 			Arch:           item.arch,
 		}),
 	)
+	m.signatureVerified.Delete(m.buildSignatureLabels(namespace, pod, container, item.image))
 	delete(m.containerCache, index)
 }
 
+// SetSignatureVerified records whether entry's image signature was
+// successfully verified. This is only meaningful for containers opted in via
+// the verify.AnnotationRequireSignature annotation.
+func (m *Metrics) SetSignatureVerified(entry *Entry, verified bool) {
+	verifiedF := 0.0
+	if verified {
+		verifiedF = 1.0
+	}
+
+	m.signatureVerified.With(
+		m.buildSignatureLabels(entry.Namespace, entry.Pod, entry.Container, entry.ImageURL),
+	).Set(verifiedF)
+}
+
+// IncSignatureVerificationErrors increments the count of errors encountered
+// while verifying entry's image signature.
+func (m *Metrics) IncSignatureVerificationErrors(entry *Entry) {
+	m.signatureVerificationErrorsTotal.With(
+		m.buildSignatureLabels(entry.Namespace, entry.Pod, entry.Container, entry.ImageURL),
+	).Inc()
+}
+
+func (m *Metrics) buildSignatureLabels(namespace, pod, container, image string) prometheus.Labels {
+	return prometheus.Labels{
+		"namespace": namespace,
+		"pod":       pod,
+		"container": container,
+		"image":     image,
+	}
+}
+
 func (m *Metrics) latestImageIndex(namespace, pod, container string) string {
 	return strings.Join([]string{namespace, pod, container}, "")
 }
@@ -172,6 +382,36 @@ func (m *Metrics) buildLabels(entry *Entry) prometheus.Labels {
 	}
 }
 
+// IncCacheHit records a digest cache hit against registry.
+func (m *Metrics) IncCacheHit(registry string) {
+	m.cacheHitsTotal.With(prometheus.Labels{"registry": registry}).Inc()
+}
+
+// IncCacheMiss records a digest cache miss against registry.
+func (m *Metrics) IncCacheMiss(registry string) {
+	m.cacheMissesTotal.With(prometheus.Labels{"registry": registry}).Inc()
+}
+
+// ObserveCheckDuration records how long an end-to-end per-container version
+// check took.
+func (m *Metrics) ObserveCheckDuration(namespace string, d time.Duration) {
+	m.checkDuration.With(prometheus.Labels{"namespace": namespace}).Observe(d.Seconds())
+}
+
+// RegistryRoundTripper wraps next with instrumentation that records
+// registryRequestDuration and registryRequestsTotal for every request it
+// makes, labelled by registry host, HTTP method, and response status class.
+// A nil next defaults to http.DefaultTransport. Passing the result as the
+// Transport of a registry client's http.Client means every registry backend
+// (Docker, GCR, ECR, ACR, self-hosted) is measured the same way.
+func (m *Metrics) RegistryRoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &instrumentedRoundTripper{next: next, metrics: m}
+}
+
 func (m *Metrics) Shutdown() error {
 	// If metrics server is not started than exit early
 	if m.Server == nil {
@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+
+	"github.com/jetstack/version-checker/pkg/notify"
+)
+
+// notifyWait is how long tests wait for AddImage's notification goroutine,
+// which runs asynchronously, to deliver (or fail to deliver) an event.
+const notifyWait = 200 * time.Millisecond
+
+type fakeVerifier struct {
+	err error
+}
+
+func (f *fakeVerifier) Verify(_ context.Context, _, _ string) error {
+	return f.err
+}
+
+type syncNotifier struct {
+	events chan notify.Event
+}
+
+func newSyncNotifier() *syncNotifier {
+	return &syncNotifier{events: make(chan notify.Event, 10)}
+}
+
+func (s *syncNotifier) Notify(_ context.Context, event notify.Event) error {
+	s.events <- event
+	return nil
+}
+
+func TestAddImageSignatureGate(t *testing.T) {
+	t.Run("successful verification leaves IsLatest untouched", func(t *testing.T) {
+		m := New(logrus.NewEntry(logrus.New()))
+		m.SetVerifier(&fakeVerifier{})
+
+		entry := &Entry{Namespace: "ns", Pod: "pod", Container: "container", ImageURL: "image", IsLatest: true, RequireSignature: true}
+		m.AddImage(entry)
+
+		if got := testutil.ToFloat64(m.containerImageVersion.With(m.buildLabels(entry))); got != 1 {
+			t.Errorf("expected is_latest_version=1, got %v", got)
+		}
+		if got := testutil.ToFloat64(m.signatureVerified.With(m.buildSignatureLabels("ns", "pod", "container", "image"))); got != 1 {
+			t.Errorf("expected signature_verified=1, got %v", got)
+		}
+	})
+
+	t.Run("failed verification forces IsLatest to false", func(t *testing.T) {
+		m := New(logrus.NewEntry(logrus.New()))
+		m.SetVerifier(&fakeVerifier{err: errors.New("signature mismatch")})
+
+		entry := &Entry{Namespace: "ns", Pod: "pod", Container: "container", ImageURL: "image", IsLatest: true, RequireSignature: true}
+		m.AddImage(entry)
+
+		if got := testutil.ToFloat64(m.containerImageVersion.With(m.buildLabels(entry))); got != 0 {
+			t.Errorf("expected is_latest_version=0 after a failed verification, got %v", got)
+		}
+		if got := testutil.ToFloat64(m.signatureVerified.With(m.buildSignatureLabels("ns", "pod", "container", "image"))); got != 0 {
+			t.Errorf("expected signature_verified=0, got %v", got)
+		}
+		if got := testutil.ToFloat64(m.signatureVerificationErrorsTotal.With(m.buildSignatureLabels("ns", "pod", "container", "image"))); got != 1 {
+			t.Errorf("expected one signature verification error recorded, got %v", got)
+		}
+	})
+
+	t.Run("RequireSignature unset skips verification entirely", func(t *testing.T) {
+		m := New(logrus.NewEntry(logrus.New()))
+		m.SetVerifier(&fakeVerifier{err: errors.New("should never be called")})
+
+		entry := &Entry{Namespace: "ns", Pod: "pod", Container: "container", ImageURL: "image", IsLatest: true}
+		m.AddImage(entry)
+
+		if got := testutil.ToFloat64(m.containerImageVersion.With(m.buildLabels(entry))); got != 1 {
+			t.Errorf("expected is_latest_version=1 when signature isn't required, got %v", got)
+		}
+	})
+}
+
+func TestAddImageNotifiesOnTransition(t *testing.T) {
+	m := New(logrus.NewEntry(logrus.New()))
+	n := newSyncNotifier()
+	m.SetNotifier(n)
+
+	entry := func(latest bool) *Entry {
+		return &Entry{Namespace: "ns", Pod: "pod", Container: "container", ImageURL: "image", IsLatest: latest}
+	}
+
+	// Latest -> outdated: a transition, so it should notify.
+	m.AddImage(entry(true))
+	m.AddImage(entry(false))
+	select {
+	case <-n.events:
+	case <-time.After(notifyWait):
+		t.Fatal("expected a notification on the latest->outdated transition")
+	}
+
+	// Still outdated: not a transition, so no further notification.
+	m.AddImage(entry(false))
+	select {
+	case <-n.events:
+		t.Fatal("expected no notification for a repeated outdated observation")
+	case <-time.After(notifyWait):
+	}
+
+	// Back to latest, no notification expected either way.
+	m.AddImage(entry(true))
+	select {
+	case <-n.events:
+		t.Fatal("expected no notification when returning to latest")
+	case <-time.After(notifyWait):
+	}
+
+	// First-ever observation of a container, already outdated: notifies.
+	other := &Entry{Namespace: "ns", Pod: "other-pod", Container: "container", ImageURL: "image", IsLatest: false}
+	m.AddImage(other)
+	select {
+	case <-n.events:
+	case <-time.After(notifyWait):
+		t.Fatal("expected a notification the first time a container is observed outdated")
+	}
+}
+
+func TestNotifyOutdatedPassesNamespaceAnnotations(t *testing.T) {
+	m := New(logrus.NewEntry(logrus.New()))
+	n := newSyncNotifier()
+	m.SetNotifier(n)
+
+	annotations := map[string]string{notify.AnnotationSinks: "slack"}
+	m.AddImage(&Entry{Namespace: "ns", Pod: "pod", Container: "container", ImageURL: "image", IsLatest: false, NamespaceAnnotations: annotations})
+
+	select {
+	case event := <-n.events:
+		if event.NamespaceAnnotations[notify.AnnotationSinks] != "slack" {
+			t.Errorf("expected NamespaceAnnotations to be threaded through to the event, got %#v", event.NamespaceAnnotations)
+		}
+	case <-time.After(notifyWait):
+		t.Fatal("expected a notification")
+	}
+}
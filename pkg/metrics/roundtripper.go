@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// instrumentedRoundTripper records registryRequestDuration and
+// registryRequestsTotal for every request it proxies to next.
+type instrumentedRoundTripper struct {
+	next    http.RoundTripper
+	metrics *Metrics
+}
+
+func (t *instrumentedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+
+	res, err := t.next.RoundTrip(req)
+
+	statusClass := "error"
+	if err == nil {
+		statusClass = strconv.Itoa(res.StatusCode/100) + "xx"
+	}
+
+	labels := prometheus.Labels{
+		"registry":     req.URL.Host,
+		"method":       req.Method,
+		"status_class": statusClass,
+	}
+
+	t.metrics.registryRequestDuration.With(labels).Observe(time.Since(start).Seconds())
+	t.metrics.registryRequestsTotal.With(labels).Inc()
+
+	return res, err
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRegistryRoundTripper(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	m := New(logrus.NewEntry(logrus.New()))
+
+	client := &http.Client{Transport: m.RegistryRoundTripper(nil)}
+
+	res, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if got := testutil.CollectAndCount(m.registryRequestsTotal); got != 1 {
+		t.Errorf("expected a single registry request to be recorded, got %d", got)
+	}
+}
+
+func TestCacheHitMissMetrics(t *testing.T) {
+	m := New(logrus.NewEntry(logrus.New()))
+
+	m.IncCacheHit("ghcr.io")
+	m.IncCacheHit("ghcr.io")
+	m.IncCacheMiss("ghcr.io")
+
+	if got := testutil.ToFloat64(m.cacheHitsTotal.WithLabelValues("ghcr.io")); got != 2 {
+		t.Errorf("expected 2 cache hits, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(m.cacheMissesTotal.WithLabelValues("ghcr.io")); got != 1 {
+		t.Errorf("expected 1 cache miss, got %v", got)
+	}
+}
@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Debouncer wraps a Notifier so that repeated Events for the same
+// (namespace, pod, container) within the debounce interval are suppressed, preventing a
+// flapping check from spamming a sink.
+type Debouncer struct {
+	next     Notifier
+	interval time.Duration
+
+	mu   sync.Mutex
+	last map[string]time.Time
+	now  func() time.Time
+}
+
+// NewDebouncer wraps next so that Events for the same container are
+// delivered at most once per interval.
+func NewDebouncer(next Notifier, interval time.Duration) *Debouncer {
+	return &Debouncer{
+		next:     next,
+		interval: interval,
+		last:     make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// Notify delivers event to the wrapped Notifier, unless an Event for the
+// same container was already delivered within the debounce interval.
+func (d *Debouncer) Notify(ctx context.Context, event Event) error {
+	key := strings.Join([]string{event.Namespace, event.Pod, event.Container}, "/")
+
+	d.mu.Lock()
+	now := d.now()
+	if last, ok := d.last[key]; ok && now.Sub(last) < d.interval {
+		d.mu.Unlock()
+		return nil
+	}
+	d.last[key] = now
+	d.mu.Unlock()
+
+	return d.next.Notify(ctx, event)
+}
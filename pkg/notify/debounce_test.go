@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type countingNotifier struct {
+	calls int
+}
+
+func (c *countingNotifier) Notify(_ context.Context, _ Event) error {
+	c.calls++
+	return nil
+}
+
+func TestDebouncer(t *testing.T) {
+	counting := &countingNotifier{}
+	d := NewDebouncer(counting, time.Minute)
+
+	now := time.Unix(0, 0)
+	d.now = func() time.Time { return now }
+
+	event := Event{Namespace: "default", Pod: "web-1", Container: "app"}
+
+	if err := d.Notify(context.TODO(), event); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Notify(context.TODO(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	if counting.calls != 1 {
+		t.Errorf("expected 1 call within the debounce interval, got %d", counting.calls)
+	}
+
+	now = now.Add(time.Hour)
+
+	if err := d.Notify(context.TODO(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	if counting.calls != 2 {
+		t.Errorf("expected a call after the debounce interval elapsed, got %d", counting.calls)
+	}
+
+	other := Event{Namespace: "default", Pod: "web-2", Container: "app"}
+	if err := d.Notify(context.TODO(), other); err != nil {
+		t.Fatal(err)
+	}
+
+	if counting.calls != 3 {
+		t.Errorf("expected a distinct container to notify independently, got %d", counting.calls)
+	}
+}
@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// EventReason is the reason recorded on Kubernetes Events emitted by
+// EventSink.
+const EventReason = "OutdatedImage"
+
+// EventSink emits a Kubernetes Event on the Pod object referenced by an
+// Event, so version drift shows up alongside a Pod's other Events without
+// requiring a separate alerting pipeline.
+type EventSink struct {
+	client kubernetes.Interface
+}
+
+// NewEventSink builds an EventSink that emits Events via client.
+func NewEventSink(client kubernetes.Interface) *EventSink {
+	return &EventSink{client: client}
+}
+
+// Notify emits a Warning Event of reason EventReason against event.Pod.
+func (s *EventSink) Notify(ctx context.Context, event Event) error {
+	pod, err := s.client.CoreV1().Pods(event.Namespace).Get(ctx, event.Pod, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to look up pod %s/%s: %s", event.Namespace, event.Pod, err)
+	}
+
+	k8sEvent := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "version-checker-",
+			Namespace:    event.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      "Pod",
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			UID:       pod.UID,
+		},
+		Reason: EventReason,
+		Message: fmt.Sprintf(
+			"container %q is running %q, upstream latest is %q (image %s)",
+			event.Container, event.CurrentVersion, event.LatestVersion, event.Image,
+		),
+		Type:           corev1.EventTypeWarning,
+		FirstTimestamp: metav1.NewTime(event.CheckedAt),
+		LastTimestamp:  metav1.NewTime(event.CheckedAt),
+		Source: corev1.EventSource{
+			Component: "version-checker",
+		},
+	}
+
+	_, err = s.client.CoreV1().Events(event.Namespace).Create(ctx, k8sEvent, metav1.CreateOptions{})
+	return err
+}
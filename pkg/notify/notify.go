@@ -0,0 +1,44 @@
+// Package notify turns version drift detected by pkg/metrics into outbound
+// notifications, so operators don't have to build their own Prometheus
+// alerting rules on top of version_checker_is_latest_version.
+package notify
+
+import (
+	"context"
+	"time"
+)
+
+// AnnotationSinks, set on a Namespace, restricts which configured sinks fire
+// for containers within it to a comma-separated subset of sink names (e.g.
+// "slack,webhook"). If unset, an Event is sent to every configured sink.
+const AnnotationSinks = "version-checker.io/notify-sinks"
+
+// Event describes a single container transitioning away from the latest
+// upstream version, or being observed as outdated for the first time.
+type Event struct {
+	Namespace      string
+	Pod            string
+	Container      string
+	Image          string
+	CurrentVersion string
+	LatestVersion  string
+	CheckedAt      time.Time
+
+	// NamespaceAnnotations are the annotations of Namespace, consulted by
+	// Router to decide which sinks to notify.
+	NamespaceAnnotations map[string]string
+}
+
+// Notifier delivers an Event to some outbound sink.
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// NoOp is a Notifier that discards every Event. It is the default when no
+// notification sinks have been configured.
+type NoOp struct{}
+
+// Notify always returns nil.
+func (NoOp) Notify(_ context.Context, _ Event) error {
+	return nil
+}
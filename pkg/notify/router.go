@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"context"
+	"strings"
+)
+
+// Router fans an Event out to a named set of sinks, letting individual
+// namespaces opt into a subset via AnnotationSinks. A namespace with no
+// AnnotationSinks annotation is notified through every registered sink.
+type Router struct {
+	sinks map[string]Notifier
+}
+
+// NewRouter builds a Router from a name -> Notifier mapping, e.g.
+// {"slack": slackSink, "webhook": webhookSink, "event": eventSink}.
+func NewRouter(sinks map[string]Notifier) *Router {
+	return &Router{sinks: sinks}
+}
+
+// Notify delivers event to every sink selected for its namespace, returning
+// the first error encountered, if any. It still attempts delivery to every
+// selected sink even if an earlier one fails.
+func (r *Router) Notify(ctx context.Context, event Event) error {
+	var firstErr error
+
+	for _, name := range r.selectedSinks(event) {
+		sink, ok := r.sinks[name]
+		if !ok {
+			continue
+		}
+
+		if err := sink.Notify(ctx, event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+func (r *Router) selectedSinks(event Event) []string {
+	raw, ok := event.NamespaceAnnotations[AnnotationSinks]
+	if !ok || raw == "" {
+		names := make([]string, 0, len(r.sinks))
+		for name := range r.sinks {
+			names = append(names, name)
+		}
+		return names
+	}
+
+	parts := strings.Split(raw, ",")
+	names := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			names = append(names, p)
+		}
+	}
+
+	return names
+}
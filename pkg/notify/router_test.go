@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRouterSelectedSinks(t *testing.T) {
+	slack := &countingNotifier{}
+	webhook := &countingNotifier{}
+	router := NewRouter(map[string]Notifier{
+		"slack":   slack,
+		"webhook": webhook,
+	})
+
+	t.Run("no annotation notifies every sink", func(t *testing.T) {
+		slack.calls, webhook.calls = 0, 0
+
+		if err := router.Notify(context.TODO(), Event{}); err != nil {
+			t.Fatal(err)
+		}
+
+		if slack.calls != 1 || webhook.calls != 1 {
+			t.Errorf("expected both sinks to fire, got slack=%d webhook=%d", slack.calls, webhook.calls)
+		}
+	})
+
+	t.Run("annotation restricts to named sinks", func(t *testing.T) {
+		slack.calls, webhook.calls = 0, 0
+
+		event := Event{NamespaceAnnotations: map[string]string{AnnotationSinks: "slack"}}
+		if err := router.Notify(context.TODO(), event); err != nil {
+			t.Fatal(err)
+		}
+
+		if slack.calls != 1 {
+			t.Errorf("expected slack sink to fire, got %d", slack.calls)
+		}
+		if webhook.calls != 0 {
+			t.Errorf("expected webhook sink not to fire, got %d", webhook.calls)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// SlackSink delivers an Event as a message to a Slack incoming webhook.
+type SlackSink struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlackSink builds a SlackSink that posts to webhookURL.
+func NewSlackSink(webhookURL string) *SlackSink {
+	return &SlackSink{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: time.Second * 5},
+	}
+}
+
+// Notify posts a human-readable summary of event to the configured Slack
+// incoming webhook.
+func (s *SlackSink) Notify(ctx context.Context, event Event) error {
+	text := fmt.Sprintf(
+		"container `%s/%s/%s` is running `%s` which is behind `%s` (image `%s`)",
+		event.Namespace, event.Pod, event.Container,
+		event.CurrentVersion, event.LatestVersion, event.Image,
+	)
+
+	body, err := json.Marshal(slackMessage{Text: text})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("slack webhook responded with %s", res.Status)
+	}
+
+	return nil
+}
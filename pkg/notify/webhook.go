@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookPayload is the JSON body posted to a generic webhook sink.
+type WebhookPayload struct {
+	Namespace      string `json:"namespace"`
+	Pod            string `json:"pod"`
+	Container      string `json:"container"`
+	Image          string `json:"image"`
+	CurrentVersion string `json:"current"`
+	LatestVersion  string `json:"latest"`
+	CheckedAt      string `json:"checkedAt"`
+}
+
+// WebhookSink delivers an Event as an HTTP POST of WebhookPayload to a
+// configured URL.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink builds a WebhookSink that posts to url.
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: time.Second * 5},
+	}
+}
+
+// Notify posts event to the configured webhook URL.
+func (w *WebhookSink) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(WebhookPayload{
+		Namespace:      event.Namespace,
+		Pod:            event.Pod,
+		Container:      event.Container,
+		Image:          event.Image,
+		CurrentVersion: event.CurrentVersion,
+		LatestVersion:  event.LatestVersion,
+		CheckedAt:      event.CheckedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded with %s", w.url, res.Status)
+	}
+
+	return nil
+}
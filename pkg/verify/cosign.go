@@ -0,0 +1,164 @@
+package verify
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	manifest "github.com/jetstack/version-checker/pkg/client/docker/manifest"
+)
+
+// signatureFetcher is the subset of *manifest.ManifestClient used by
+// CosignVerifier, extracted as an interface so it can be substituted with a
+// fake registry backend in tests.
+type signatureFetcher interface {
+	SignatureArtifact(ctx context.Context, ref manifest.Ref) ([]byte, string, error)
+}
+
+// CosignOptions configures a CosignVerifier. Exactly one of PublicKeyPath,
+// KMSKeyRef, or FulcioIdentity should be set.
+type CosignOptions struct {
+	// PublicKeyPath is a path to a PEM-encoded ECDSA public key used to
+	// verify signatures directly.
+	PublicKeyPath string
+
+	// KMSKeyRef is a KMS key reference (e.g. "awskms:///alias/cosign") used
+	// to verify signatures without a local key file.
+	KMSKeyRef string
+
+	// FulcioIdentity is the expected keyless-signing identity to check a
+	// certificate's SAN against, for Fulcio/Rekor verification.
+	FulcioIdentity string
+}
+
+// CosignVerifier verifies image signatures published using the cosign
+// convention: a detached signature stored as an OCI artifact tagged
+// "sha256-<digest>.sig" in the same repository as the image it signs.
+type CosignVerifier struct {
+	manifest signatureFetcher
+	key      *ecdsa.PublicKey
+}
+
+// NewCosignVerifier builds a CosignVerifier, reusing manifestClient to
+// authenticate against the same registries version-checker already talks to.
+//
+// Only PublicKeyPath is currently supported; KMSKeyRef and FulcioIdentity
+// are accepted so config wiring can be added ahead of the verification
+// logic, but return an error until implemented.
+func NewCosignVerifier(manifestClient *manifest.ManifestClient, opts CosignOptions) (*CosignVerifier, error) {
+	switch {
+	case opts.PublicKeyPath != "":
+		key, err := loadECDSAPublicKey(opts.PublicKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load cosign public key: %s", err)
+		}
+		return &CosignVerifier{manifest: manifestClient, key: key}, nil
+
+	case opts.KMSKeyRef != "":
+		return nil, errors.New("cosign KMS key verification is not yet supported")
+
+	case opts.FulcioIdentity != "":
+		return nil, errors.New("cosign keyless (Fulcio/Rekor) verification is not yet supported")
+
+	default:
+		return nil, errors.New("no cosign verification key configured")
+	}
+}
+
+func loadECDSAPublicKey(path string) (*ecdsa.PublicKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block")
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	ecKey, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not ECDSA")
+	}
+
+	return ecKey, nil
+}
+
+// signatureTag returns the cosign convention tag for a digest, e.g.
+// "sha256:abcd" becomes "sha256-abcd.sig".
+func signatureTag(digest string) string {
+	return strings.Replace(digest, ":", "-", 1) + ".sig"
+}
+
+// signedPayload is the cosign "simple signing" payload format: a signature
+// covers this JSON document, which in turn binds the signature to a single
+// image digest.
+type signedPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// Verify resolves the cosign signature artifact for ref@digest and verifies
+// it against the configured public key.
+func (v *CosignVerifier) Verify(ctx context.Context, ref, digest string) error {
+	imageRef, err := manifest.ParseRef(ref)
+	if err != nil {
+		return err
+	}
+
+	sigRef := manifest.Ref{
+		Registry:   imageRef.Registry,
+		Repository: imageRef.Repository,
+		Tag:        signatureTag(digest),
+	}
+
+	payload, sigBase64, err := v.manifest.SignatureArtifact(ctx, sigRef)
+	if err != nil {
+		return fmt.Errorf("failed to fetch signature for %s: %s", ref, err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(sigBase64)
+	if err != nil {
+		return fmt.Errorf("failed to decode signature for %s: %s", ref, err)
+	}
+
+	if len(sig) != 64 {
+		return fmt.Errorf("unexpected signature length %d for %s", len(sig), ref)
+	}
+
+	hashed := sha256.Sum256(payload)
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+
+	if !ecdsa.Verify(v.key, hashed[:], r, s) {
+		return fmt.Errorf("signature verification failed for %s", ref)
+	}
+
+	var signed signedPayload
+	if err := json.Unmarshal(payload, &signed); err != nil {
+		return fmt.Errorf("failed to parse signed payload for %s: %s", ref, err)
+	}
+
+	if signed.Critical.Image.DockerManifestDigest != digest {
+		return fmt.Errorf("signature for %s is for digest %s, not %s", ref, signed.Critical.Image.DockerManifestDigest, digest)
+	}
+
+	return nil
+}
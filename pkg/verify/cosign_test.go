@@ -0,0 +1,91 @@
+package verify
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	manifest "github.com/jetstack/version-checker/pkg/client/docker/manifest"
+)
+
+type fakeSignatureFetcher struct {
+	payload []byte
+	sigB64  string
+}
+
+func (f *fakeSignatureFetcher) SignatureArtifact(_ context.Context, _ manifest.Ref) ([]byte, string, error) {
+	return f.payload, f.sigB64, nil
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, payload []byte) string {
+	t.Helper()
+
+	hashed := sha256.Sum256(payload)
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func TestCosignVerifierVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := []byte(`{"critical":{"image":{"docker-manifest-digest":"sha256:abcd"}}}`)
+	validSig := sign(t, key, payload)
+
+	t.Run("accepts a valid signature", func(t *testing.T) {
+		v := &CosignVerifier{manifest: &fakeSignatureFetcher{payload: payload, sigB64: validSig}, key: &key.PublicKey}
+
+		if err := v.Verify(context.TODO(), "ghcr.io/jetstack/version-checker:v1.0.0", "sha256:abcd"); err != nil {
+			t.Errorf("expected verification to succeed, got: %s", err)
+		}
+	})
+
+	t.Run("rejects a tampered payload", func(t *testing.T) {
+		tampered := append(append([]byte{}, payload...), '!')
+		v := &CosignVerifier{manifest: &fakeSignatureFetcher{payload: tampered, sigB64: validSig}, key: &key.PublicKey}
+
+		if err := v.Verify(context.TODO(), "ghcr.io/jetstack/version-checker:v1.0.0", "sha256:abcd"); err == nil {
+			t.Error("expected verification to fail for a tampered payload")
+		}
+	})
+
+	t.Run("rejects a signature from a different key", func(t *testing.T) {
+		other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wrongSig := sign(t, other, payload)
+
+		v := &CosignVerifier{manifest: &fakeSignatureFetcher{payload: payload, sigB64: wrongSig}, key: &key.PublicKey}
+
+		if err := v.Verify(context.TODO(), "ghcr.io/jetstack/version-checker:v1.0.0", "sha256:abcd"); err == nil {
+			t.Error("expected verification to fail for a signature from a different key")
+		}
+	})
+
+	t.Run("rejects a validly-signed payload for a different digest", func(t *testing.T) {
+		// A legitimately-signed payload for one digest must not verify
+		// against a different digest: otherwise a valid signature copied
+		// onto the sha256-<digest>.sig tag of another image would verify.
+		v := &CosignVerifier{manifest: &fakeSignatureFetcher{payload: payload, sigB64: validSig}, key: &key.PublicKey}
+
+		if err := v.Verify(context.TODO(), "ghcr.io/jetstack/version-checker:v1.0.0", "sha256:different"); err == nil {
+			t.Error("expected verification to fail when the signed payload's digest doesn't match")
+		}
+	})
+}
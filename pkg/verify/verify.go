@@ -0,0 +1,35 @@
+// Package verify gates whether an image is allowed to be reported as the
+// latest version on it carrying a valid signature, in the spirit of
+// sigstore/cosign and Notary.
+package verify
+
+import "context"
+
+// AnnotationRequireSignature, when set to "true" on a Pod, opts its
+// containers into signature verification: a container's image will only be
+// reported as is_latest_version if its signature also verifies.
+const AnnotationRequireSignature = "version-checker.io/require-signature"
+
+// Verifier verifies that the image identified by ref, resolved to digest,
+// carries a valid signature. Implementations should return a non-nil error
+// describing why verification failed, rather than a boolean, so the reason
+// can be logged and surfaced in the verification-errors metric.
+type Verifier interface {
+	Verify(ctx context.Context, ref, digest string) error
+}
+
+// NoOp is the default Verifier used when no signature verification has been
+// configured. It always succeeds, so version-checker's existing behaviour is
+// unchanged unless a Verifier is explicitly wired in.
+type NoOp struct{}
+
+// Verify always returns nil.
+func (NoOp) Verify(_ context.Context, _, _ string) error {
+	return nil
+}
+
+// RequireSignature reports whether the given Pod annotations opt into
+// signature verification via AnnotationRequireSignature.
+func RequireSignature(annotations map[string]string) bool {
+	return annotations[AnnotationRequireSignature] == "true"
+}
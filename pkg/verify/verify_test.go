@@ -0,0 +1,53 @@
+package verify
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNoOp(t *testing.T) {
+	if err := (NoOp{}).Verify(context.TODO(), "example.com/image:v1", "sha256:abcd"); err != nil {
+		t.Errorf("expected NoOp.Verify to always succeed, got: %s", err)
+	}
+}
+
+func TestRequireSignature(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		exp         bool
+	}{
+		"annotation set to true":        {annotations: map[string]string{AnnotationRequireSignature: "true"}, exp: true},
+		"annotation set to false":       {annotations: map[string]string{AnnotationRequireSignature: "false"}, exp: false},
+		"annotation absent":             {annotations: map[string]string{}, exp: false},
+		"nil annotations":               {annotations: nil, exp: false},
+		"unrelated annotations present": {annotations: map[string]string{"foo": "bar"}, exp: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := RequireSignature(test.annotations); got != test.exp {
+				t.Errorf("exp=%v got=%v", test.exp, got)
+			}
+		})
+	}
+}
+
+func TestSignatureTag(t *testing.T) {
+	tests := map[string]struct {
+		digest string
+		exp    string
+	}{
+		"sha256 digest": {
+			digest: "sha256:1234abcd",
+			exp:    "sha256-1234abcd.sig",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := signatureTag(test.digest); got != test.exp {
+				t.Errorf("exp=%s got=%s", test.exp, got)
+			}
+		})
+	}
+}